@@ -0,0 +1,169 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.uber.org/fx/internal/lifecycle"
+)
+
+// App is a modular application built around dependency injection. Most
+// users only interact with an App via New, Provide and Invoke.
+type App struct {
+	lifecycle *lifecycleWrapper
+	log       Printer
+	logger    Logger
+
+	constructors []interface{}
+	invokes      []interface{}
+
+	values map[reflect.Type]reflect.Value
+
+	shutdownTimeout time.Duration
+	startTimeout    time.Duration
+	stopTimeout     time.Duration
+
+	err error
+}
+
+// New creates a new App, applying the given Options and running any
+// registered Invoke functions.
+func New(opts ...Option) *App {
+	app := &App{
+		log:    DefaultLogger,
+		values: make(map[reflect.Type]reflect.Value),
+	}
+
+	for _, opt := range opts {
+		opt.apply(app)
+	}
+
+	if app.logger == nil {
+		app.logger = printerLogger{p: app.log}
+	}
+
+	lc := lifecycle.New(lifecycleLogger{l: app.logger})
+	lc.SetStartTimeout(app.startTimeout)
+	lc.SetStopTimeout(app.stopTimeout)
+	app.lifecycle = &lifecycleWrapper{lc: lc}
+	app.values[reflect.TypeOf((*Lifecycle)(nil)).Elem()] = reflect.ValueOf(app.lifecycle)
+
+	for _, ctor := range app.constructors {
+		if err := app.build(ctor); err != nil {
+			app.err = err
+			return app
+		}
+	}
+
+	for _, fn := range app.invokes {
+		if err := app.invoke(fn); err != nil {
+			app.err = err
+			return app
+		}
+	}
+
+	return app
+}
+
+// build calls a zero-argument constructor registered via Provide and
+// records its result so it can be supplied to later Invoke calls.
+func (app *App) build(ctor interface{}) error {
+	fv := reflect.ValueOf(ctor)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 0 || ft.NumOut() != 1 {
+		err := fmt.Errorf("fx.Provide: %T must be a func with no arguments and one return value", ctor)
+		app.logger.Error("provide failed", F("constructor", fmt.Sprintf("%T", ctor)), F("error", err))
+		return err
+	}
+	out := fv.Call(nil)
+	app.values[ft.Out(0)] = out[0]
+	app.logger.Debug("provided", F("constructor", ft.String()), F("type", ft.Out(0).String()))
+	return nil
+}
+
+// invoke calls a function registered via Invoke, resolving its arguments
+// from values registered with Provide.
+func (app *App) invoke(fn interface{}) error {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+
+	args := make([]reflect.Value, ft.NumIn())
+	for i := range args {
+		in := ft.In(i)
+		v, ok := app.values[in]
+		if !ok {
+			err := fmt.Errorf("fx.Invoke: no constructor provided for %s", in)
+			app.logger.Error("invoke failed", F("invoke", ft.String()), F("error", err))
+			return err
+		}
+		args[i] = v
+	}
+
+	out := fv.Call(args)
+	for _, v := range out {
+		if err, ok := v.Interface().(error); ok && err != nil {
+			app.logger.Error("invoke failed", F("invoke", ft.String()), F("error", err))
+			return err
+		}
+	}
+	app.logger.Info("invoked", F("invoke", ft.String()))
+	return nil
+}
+
+// Err returns any error encountered while building the App, such as a
+// failed constructor or Invoke call.
+func (app *App) Err() error {
+	return app.err
+}
+
+// Start kicks off all long-running goroutines, like network servers or
+// message queue consumers, by running all OnStart hooks registered with
+// the App's Lifecycle.
+func (app *App) Start(ctx context.Context) error {
+	if app.err != nil {
+		return app.err
+	}
+	start := time.Now()
+	err := app.lifecycle.Start(ctx)
+	if err != nil {
+		app.logger.Error("start failed", F("duration", time.Since(start)), F("error", err))
+		return err
+	}
+	app.logger.Info("started", F("duration", time.Since(start)))
+	return nil
+}
+
+// Stop gracefully stops the App by running any OnStop hooks registered
+// with the App's Lifecycle.
+func (app *App) Stop(ctx context.Context) error {
+	start := time.Now()
+	err := app.lifecycle.Stop(ctx)
+	if err != nil {
+		app.logger.Error("stop failed", F("duration", time.Since(start)), F("error", err))
+		return err
+	}
+	app.logger.Info("stopped", F("duration", time.Since(start)))
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeLogger records every structured log entry it receives, for assertions
+// on what an App reports for provide/invoke/lifecycle events.
+type fakeLogger struct {
+	entries []string
+}
+
+func (f *fakeLogger) Debug(msg string, fields ...Field) { f.entries = append(f.entries, "DEBUG:"+msg) }
+func (f *fakeLogger) Info(msg string, fields ...Field)  { f.entries = append(f.entries, "INFO:"+msg) }
+func (f *fakeLogger) Warn(msg string, fields ...Field)  { f.entries = append(f.entries, "WARN:"+msg) }
+func (f *fakeLogger) Error(msg string, fields ...Field) { f.entries = append(f.entries, "ERROR:"+msg) }
+
+func TestApp(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "Provide and Invoke resolve constructors",
+			run: func(t *testing.T) {
+				var got string
+				app := New(
+					Provide(func() string { return "hello" }),
+					Invoke(func(s string) error { got = s; return nil }),
+				)
+				if err := app.Err(); err != nil {
+					t.Fatalf("Err: %v", err)
+				}
+				if got != "hello" {
+					t.Fatalf("got = %q, want hello", got)
+				}
+			},
+		},
+		{
+			name: "Provide rejects a constructor with the wrong shape",
+			run: func(t *testing.T) {
+				app := New(Provide(func(int) {}))
+				if app.Err() == nil {
+					t.Fatal("Err: got nil, want an error for a malformed constructor")
+				}
+			},
+		},
+		{
+			name: "Invoke rejects an unresolved dependency",
+			run: func(t *testing.T) {
+				app := New(Invoke(func(int) error { return nil }))
+				if app.Err() == nil {
+					t.Fatal("Err: got nil, want an error for an unresolved dependency")
+				}
+			},
+		},
+		{
+			name: "Invoke surfaces the returned error",
+			run: func(t *testing.T) {
+				want := errors.New("invoke failed")
+				app := New(Invoke(func() error { return want }))
+				if got := app.Err(); got != want {
+					t.Fatalf("Err: got %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "Start and Stop run hooks registered through Lifecycle",
+			run: func(t *testing.T) {
+				var order []string
+				app := New(Invoke(func(lc Lifecycle) {
+					lc.Append(Hook{
+						OnStart: func(context.Context) error { order = append(order, "start"); return nil },
+						OnStop:  func(context.Context) error { order = append(order, "stop"); return nil },
+					})
+				}))
+				if err := app.Start(context.Background()); err != nil {
+					t.Fatalf("Start: %v", err)
+				}
+				if err := app.Stop(context.Background()); err != nil {
+					t.Fatalf("Stop: %v", err)
+				}
+				if len(order) != 2 || order[0] != "start" || order[1] != "stop" {
+					t.Fatalf("order = %v, want [start stop]", order)
+				}
+			},
+		},
+		{
+			name: "Start returns the build error instead of running hooks",
+			run: func(t *testing.T) {
+				ran := false
+				app := New(
+					Provide(func(int) {}),
+					Invoke(func(lc Lifecycle) {
+						lc.Append(Hook{OnStart: func(context.Context) error { ran = true; return nil }})
+					}),
+				)
+				if err := app.Start(context.Background()); err == nil {
+					t.Fatal("Start: got nil error, want the build error")
+				}
+				if ran {
+					t.Fatal("Start ran a hook despite a build error")
+				}
+			},
+		},
+		{
+			name: "StartTimeout cancels the context passed to a slow OnStart hook",
+			run: func(t *testing.T) {
+				app := New(
+					StartTimeout(10*time.Millisecond),
+					Invoke(func(lc Lifecycle) {
+						lc.Append(Hook{OnStart: func(ctx context.Context) error {
+							<-ctx.Done()
+							return ctx.Err()
+						}})
+					}),
+				)
+				if err := app.Start(context.Background()); err != context.DeadlineExceeded {
+					t.Fatalf("Start: got %v, want context.DeadlineExceeded", err)
+				}
+			},
+		},
+		{
+			name: "WithLogger reports one entry per provide/invoke/lifecycle event",
+			run: func(t *testing.T) {
+				logger := &fakeLogger{}
+				app := New(
+					WithLogger(logger),
+					Provide(func() string { return "hello" }),
+					Invoke(func(string) error { return nil }),
+					Invoke(func(lc Lifecycle) {
+						lc.Append(Hook{OnStart: func(context.Context) error { return nil }})
+					}),
+				)
+				if err := app.Start(context.Background()); err != nil {
+					t.Fatalf("Start: %v", err)
+				}
+
+				want := []string{"DEBUG:provided", "INFO:invoked", "INFO:invoked", "DEBUG:OnStart", "INFO:started"}
+				if len(logger.entries) != len(want) {
+					t.Fatalf("logger.entries = %v, want %v", logger.entries, want)
+				}
+				for i := range want {
+					if logger.entries[i] != want[i] {
+						t.Fatalf("logger.entries = %v, want %v", logger.entries, want)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
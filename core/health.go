@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// HealthStatus is a coarse-grained lifecycle state for a Service, following
+// the STARTING/READY/STOPPING/STOPPED progression that Start and Stop move
+// it through.
+type HealthStatus int
+
+const (
+	// HealthStarting means Start has been called but not every module has
+	// reported itself ready yet.
+	HealthStarting HealthStatus = iota
+	// HealthReady means every started module that implements
+	// ModuleReadiness has returned a nil error from Ready().
+	HealthReady
+	// HealthStopping means Stop has been called and modules are being torn
+	// down.
+	HealthStopping
+	// HealthStopped means the service has fully shut down.
+	HealthStopped
+)
+
+func (h HealthStatus) String() string {
+	switch h {
+	case HealthStarting:
+		return "STARTING"
+	case HealthReady:
+		return "READY"
+	case HealthStopping:
+		return "STOPPING"
+	case HealthStopped:
+		return "STOPPED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ModuleReadiness is implemented by Modules that have their own notion of
+// readiness beyond "Start has returned", such as an HTTP module that isn't
+// ready until it has finished an initial cache warmup. It's checked with a
+// type assertion like ModuleDependencies, so modules that are ready as soon
+// as they start don't need to implement it.
+type ModuleReadiness interface {
+	Ready() error
+}
+
+// moduleReady returns m's readiness, or nil if it doesn't implement
+// ModuleReadiness.
+func moduleReady(m Module) error {
+	if r, ok := m.(ModuleReadiness); ok {
+		return r.Ready()
+	}
+	return nil
+}
+
+// readinessPollInterval is how often Start's background goroutine re-checks
+// module readiness while waiting to report HealthReady.
+const readinessPollInterval = 250 * time.Millisecond
+
+// mu guards status and readyStop below; healthState is declared here
+// rather than in service.go because the whole health subsystem lives in
+// this file.
+type healthState struct {
+	mu        sync.RWMutex
+	status    HealthStatus
+	readyStop chan struct{}
+}
+
+// HealthStatus returns the service's current coarse-grained health state.
+func (s *Service) HealthStatus() HealthStatus {
+	s.health.mu.RLock()
+	defer s.health.mu.RUnlock()
+	return s.health.status
+}
+
+// SetHealthStatus updates the service's health state and records the
+// transition as a gauge on the service's metrics Scope.
+func (s *Service) SetHealthStatus(status HealthStatus) {
+	s.health.mu.Lock()
+	changed := s.health.status != status
+	s.health.status = status
+	s.health.mu.Unlock()
+
+	if changed {
+		s.Scope().Gauge("health.status").Update(float64(status))
+		log.Printf("health status -> %s", status)
+	}
+}
+
+// modulesReady reports whether every module implementing ModuleReadiness
+// currently reports itself ready.
+func (s *Service) modulesReady() error {
+	for _, m := range s.modules {
+		if err := moduleReady(m); err != nil {
+			return fmt.Errorf("module %q not ready: %w", m.Name(), err)
+		}
+	}
+	return nil
+}
+
+// watchReadiness polls modulesReady until every module is ready (at which
+// point it reports HealthReady) or stop is closed by shutdown.
+func (s *Service) watchReadiness(stop <-chan struct{}) {
+	if s.modulesReady() == nil {
+		s.SetHealthStatus(HealthReady)
+		return
+	}
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if s.modulesReady() == nil {
+				s.SetHealthStatus(HealthReady)
+				return
+			}
+		}
+	}
+}
+
+// WaitReady blocks until the service reports HealthReady, or ctx is done.
+func (s *Service) WaitReady(ctx context.Context) error {
+	if s.HealthStatus() == HealthReady {
+		return nil
+	}
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if s.HealthStatus() == HealthReady {
+				return nil
+			}
+		}
+	}
+}
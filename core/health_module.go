@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthModule is a built-in Module that serves /livez (process alive) and
+// /readyz (every module ready, per Service.HealthStatus) over HTTP. Create
+// one with NewHealthModuleCreator and pass it alongside a Service's other
+// ModuleCreateFuncs.
+type healthModule struct {
+	svc  *Service
+	addr string
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+	running  bool
+}
+
+// NewHealthModuleCreator returns a ModuleCreateFunc that builds a Module
+// serving /livez and /readyz on addr (e.g. ":8080").
+func NewHealthModuleCreator(addr string) ModuleCreateFunc {
+	return func(svc *Service) ([]Module, error) {
+		return []Module{&healthModule{svc: svc, addr: addr}}, nil
+	}
+}
+
+func (m *healthModule) Name() string    { return "health" }
+func (m *healthModule) Roles() []string { return nil }
+
+func (m *healthModule) IsRunning() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running
+}
+
+func (m *healthModule) Start() <-chan error {
+	result := make(chan error, 1)
+
+	ln, err := net.Listen("tcp", m.addr)
+	if err != nil {
+		result <- err
+		return result
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/livez", m.handleLivez)
+	mux.HandleFunc("/readyz", m.handleReadyz)
+	server := &http.Server{Handler: mux}
+
+	m.mu.Lock()
+	m.listener = ln
+	m.server = server
+	m.running = true
+	m.mu.Unlock()
+
+	go server.Serve(ln)
+
+	result <- nil
+	return result
+}
+
+func (m *healthModule) Stop() error {
+	m.mu.Lock()
+	server := m.server
+	m.running = false
+	m.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
+
+// handleLivez reports 200 as long as the process is up; it doesn't check
+// module readiness.
+func (m *healthModule) handleLivez(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// handleReadyz reports 200 once the Service's HealthStatus is HealthReady,
+// and 503 with the current status otherwise.
+func (m *healthModule) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	status := m.svc.HealthStatus()
+	if status == HealthReady {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+		return
+	}
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write([]byte(status.String()))
+}
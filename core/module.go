@@ -0,0 +1,43 @@
+package core
+
+// Module represents a unit of service functionality that can be started and
+// stopped independently, such as an HTTP server, an RPC client, or a
+// background worker.
+type Module interface {
+	// Name returns a name uniquely identifying this module within a
+	// Service.
+	Name() string
+
+	// Roles restricts which service roles load this module. A module with
+	// no roles is loaded regardless of the roles the Service is configured
+	// with.
+	Roles() []string
+
+	// IsRunning reports whether the module has already been started.
+	IsRunning() bool
+
+	// Start starts the module, reporting completion (and any error) on the
+	// returned channel.
+	Start() <-chan error
+
+	// Stop stops the module.
+	Stop() error
+}
+
+// ModuleDependencies is implemented by Modules that must be started after -
+// and stopped before - a set of other modules, identified by their Name().
+// It's detected with a type assertion rather than being part of Module, so
+// modules that don't care about ordering don't need to implement it.
+type ModuleDependencies interface {
+	// Dependencies returns the names of the modules this module depends on.
+	Dependencies() []string
+}
+
+// moduleDependencies returns m's declared dependencies, or nil if it doesn't
+// implement ModuleDependencies.
+func moduleDependencies(m Module) []string {
+	if d, ok := m.(ModuleDependencies); ok {
+		return d.Dependencies()
+	}
+	return nil
+}
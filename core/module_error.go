@@ -0,0 +1,57 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// moduleStopError aggregates the errors returned by one or more modules'
+// Start or Stop calls, keyed by module name, into a single error.
+type moduleStopError struct {
+	errs map[string]error
+}
+
+func (e *moduleStopError) Error() string {
+	names := make([]string, 0, len(e.errs))
+	for name := range e.errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("errModuleStopError:")
+	for _, name := range names {
+		b.WriteString("\n  ")
+		b.WriteString(name)
+		b.WriteString(": ")
+		b.WriteString(e.errs[name].Error())
+	}
+	return b.String()
+}
+
+// Errors returns the per-module errors that were aggregated, keyed by
+// module name.
+func (e *moduleStopError) Errors() map[string]error {
+	return e.errs
+}
+
+// aggregateModuleErrors collects the non-nil errors in errs (as returned by
+// startModules or stopModules) into a single *moduleStopError, or returns
+// nil if every module succeeded.
+func aggregateModuleErrors(errs map[Module]error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	byName := make(map[string]error, len(errs))
+	for m, err := range errs {
+		if err != nil {
+			byName[m.Name()] = err
+		}
+	}
+	if len(byName) == 0 {
+		return nil
+	}
+
+	return &moduleStopError{errs: byName}
+}
@@ -1,12 +1,18 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/uber-go/uberfx/core/config"
 	cm "github.com/uber-go/uberfx/core/metrics"
@@ -19,15 +25,35 @@ type Service struct {
 	modules  []Module
 	roles    []string
 	instance ServiceInstance
-	scope    metrics.Scope
+	scope    cm.Scope
 
-	// Shutdown fields.
-	shutdownMu     sync.Mutex
-	inShutdown     bool         // Protected by shutdownMu
-	shutdownReason *ServiceExit // Protected by shutdownMu
-	closeChan      chan ServiceExit
+	config serviceConfig
+
+	// Shutdown fields. mu guards everything below; stopOnce ensures Stop's
+	// teardown logic (module Stop calls, health transitions, OnShutdown)
+	// runs exactly once no matter how many goroutines call Stop or how a
+	// shutdown was triggered (explicit Stop, a critical error, or a
+	// partial Start failure).
+	mu             sync.Mutex
 	started        bool
-	config         serviceConfig
+	stopped        bool
+	quitCh         chan struct{} // closed exactly once, when Stop's teardown finishes
+	stopOnce       sync.Once
+	stopErr        error        // the triggering error if any, else the aggregated module-stop error; valid once quitCh is closed
+	shutdownReason *ServiceExit // valid once quitCh is closed
+
+	// Module dependency DAG, keyed by Module.Name(). dependencies[a][b] set
+	// means a depends on b; dependents[b][a] is the inverse edge. Both are
+	// built once in NewService and only read afterwards, but are guarded
+	// since Start and Stop may run concurrently with inspection (e.g. from
+	// tests or an admin endpoint).
+	dagMu        sync.RWMutex
+	dependencies map[string]map[string]bool
+	dependents   map[string]map[string]bool
+
+	// health tracks the service's coarse-grained STARTING/READY/STOPPING/
+	// STOPPED state. See health.go.
+	health healthState
 }
 
 // ServiceInstance is the interface that is implemented by user service/
@@ -56,6 +82,11 @@ type serviceConfig struct {
 	ServiceOwner       string   `yaml:"applicationowner"  required:"true"`
 	ServiceDescription string   `yaml:"applicationdesc"`
 	ServiceRoles       []string `yaml:"roles"`
+
+	// ShutdownTimeout bounds how long stopModules waits for each module's
+	// Stop to return before giving up on it and moving on. Zero means use
+	// defaultShutdownTimeout.
+	ShutdownTimeout time.Duration `yaml:"shutdowntimeout"`
 }
 
 // type LoadModuleServicesFunc func(*Service) []Module
@@ -125,6 +156,13 @@ func NewService(instance ServiceInstance, cfg config.ConfigurationProvider, modu
 		}
 	}
 
+	dependencies, dependents, err := buildModuleDAG(svc.modules)
+	if err != nil {
+		panic(err)
+	}
+	svc.dependencies = dependencies
+	svc.dependents = dependents
+
 	// if we have an instance, look for a property called "config" and load the service
 	// node into that config.
 	if instance != nil {
@@ -168,7 +206,13 @@ func (s *Service) Roles() []string {
 	return s.roles
 }
 
-func (s *Service) Scope() metrics.Scope {
+// Scope returns the service's metrics scope. It's never nil: a Service
+// constructed directly (e.g. in tests, bypassing NewService) gets a no-op
+// scope instead of panicking the first time something records a metric.
+func (s *Service) Scope() cm.Scope {
+	if s.scope == nil {
+		s.scope = cm.Global(true)
+	}
 	return s.scope
 }
 func (s *Service) Modules() []Module {
@@ -177,172 +221,440 @@ func (s *Service) Modules() []Module {
 	return mods
 }
 
-func (s *Service) isRunning() bool {
-	return s.closeChan != nil
-}
-
 func (s *Service) OnCriticalError(err error) {
 	if !s.instance.OnCriticalError(err) {
-		s.shutdown(err, "", nil)
+		s.stopWithReason(err, err.Error(), 1)
 	}
 }
 
-func (s *Service) shutdown(err error, reason string, exitCode *int) (bool, error) {
-
-	s.shutdownMu.Lock()
-	s.inShutdown = true
-	defer func() {
-		s.inShutdown = false
-		s.shutdownMu.Unlock()
-	}()
-
-	if s.shutdownReason != nil || !s.isRunning() {
-		return false, nil
+// Start initializes the instance (if any) and starts every module,
+// returning a channel that's closed once the service has fully stopped. If
+// waitForShutdown is true, Start blocks on that channel before returning.
+//
+// Calling Start again while the service is already running is a no-op that
+// returns the same channel; calling it after the service has stopped
+// returns an error, since a Service can't be restarted.
+func (s *Service) Start(waitForShutdown bool) (<-chan struct{}, error) {
+	s.mu.Lock()
+	if s.stopped {
+		quitCh := s.quitCh
+		s.mu.Unlock()
+		return quitCh, errors.New("errServiceAlreadyStopped")
 	}
-
-	s.shutdownReason = &ServiceExit{
-		Reason:   reason,
-		Error:    err,
-		ExitCode: 0,
+	if s.started {
+		quitCh := s.quitCh
+		s.mu.Unlock()
+		return quitCh, nil
 	}
+	if s.quitCh == nil {
+		s.quitCh = make(chan struct{})
+	}
+	s.started = true
+	quitCh := s.quitCh
+	s.mu.Unlock()
 
-	if err != nil {
-		if reason != "" {
-			s.shutdownReason.Reason = err.Error()
+	if s.instance != nil {
+		if err := s.instance.OnInit(s); err != nil {
+			s.stopWithReason(err, "errOnInitFailed", 1)
+			return quitCh, err
 		}
-		s.shutdownReason.ExitCode = 1
 	}
 
-	if exitCode != nil {
-		s.shutdownReason.ExitCode = *exitCode
+	s.SetHealthStatus(HealthStarting)
+	if errs := s.startModules(); len(errs) > 0 {
+		err := aggregateModuleErrors(errs)
+		s.stopWithReason(err, "module start failed", 1)
+		return quitCh, err
 	}
 
-	s.stopModules()
+	s.health.readyStop = make(chan struct{})
+	go s.watchReadiness(s.health.readyStop)
 
-	// TODO: What do we do with shutdown errors?
-	// if len(errs) > 0 {
-	// 	errList := "errModuleStopError\n"
-	// 	for k, v := range errs {
-	// 		errList += fmt.Sprintf("Module %q: %s\n", k.Name(), v.Error())
-	// 	}
-
-	// }
+	if waitForShutdown {
+		<-quitCh
+	}
 
-	// report that we shutdown.
-	s.closeChan <- *s.shutdownReason
-	close(s.closeChan)
+	return quitCh, nil
+}
 
-	if s.instance != nil {
-		s.instance.OnShutdown(*s.shutdownReason)
-	}
-	return true, err
+// Stop tears down every started module and reports the aggregated error (if
+// any) to instance.OnShutdown. It's safe to call from any goroutine, any
+// number of times, concurrently with itself or with Start: only the first
+// call runs teardown, and every call - including ones that arrive after
+// teardown has already finished - blocks until it's done and returns the
+// same aggregated error.
+func (s *Service) Stop(reason string, exitCode int) error {
+	return s.stopWithReason(nil, reason, exitCode)
 }
 
-// Start runs the serve loop. If Shutdown() was called then the shutdown reason
-// will be returned.
-func (s *Service) Start(waitForShutdown bool) (<-chan ServiceExit, error) {
-	var err error
-	s.shutdownMu.Lock()
-	defer s.shutdownMu.Unlock()
-
-	if s.inShutdown {
-		return nil, errors.New("errShuttingDown")
-	} else if s.isRunning() {
-		return s.closeChan, nil
-	} else {
-
-		if s.instance != nil {
-			if err := s.instance.OnInit(s); err != nil {
-				return nil, err
-			}
+// stopWithReason is Stop's implementation, plus a triggering error (from a
+// critical error or a failed Start) to record on the ServiceExit alongside
+// whatever stopModules itself reports.
+func (s *Service) stopWithReason(triggerErr error, reason string, exitCode int) error {
+	s.stopOnce.Do(func() {
+		s.mu.Lock()
+		s.stopped = true
+		started := s.started
+		if s.quitCh == nil {
+			s.quitCh = make(chan struct{})
 		}
-		s.shutdownReason = nil
-		s.closeChan = make(chan ServiceExit)
-		errs := s.startModules()
-		if len(errs) > 0 {
-			// grab the first error, shut down the service
-			// and return the error
-			for _, e := range errs {
-
-				errChan := make(chan ServiceExit)
-				errChan <- *s.shutdownReason
-				s.shutdown(e, "", nil)
-				return errChan, e
+		quitCh := s.quitCh
+		s.mu.Unlock()
+
+		result := ServiceExit{Reason: reason, Error: triggerErr, ExitCode: exitCode}
+
+		if started {
+			s.SetHealthStatus(HealthStopping)
+			if s.health.readyStop != nil {
+				close(s.health.readyStop)
+				s.health.readyStop = nil
+			}
+
+			if stopErr := aggregateModuleErrors(s.stopModules()); stopErr != nil {
+				if result.Error == nil {
+					result.Error = stopErr
+				}
+				if result.ExitCode == 0 {
+					result.ExitCode = 1
+				}
+			}
+
+			s.SetHealthStatus(HealthStopped)
+
+			if s.instance != nil {
+				s.instance.OnShutdown(result)
 			}
 		}
-	}
 
-	if waitForShutdown {
-		s.WaitForShutdown(nil)
+		// result.Error already folds the triggering error (from a critical
+		// error or a failed Start) together with any module-stop error,
+		// with the trigger taking precedence since it's the root cause;
+		// stopErr is what Stop/Wait return, so it must track it exactly.
+		s.mu.Lock()
+		s.stopErr = result.Error
+		s.shutdownReason = &result
+		s.mu.Unlock()
+
+		close(quitCh)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopErr
+}
+
+// Wait blocks until the service has fully stopped, however that was
+// triggered, and returns the same error Stop would.
+// It returns immediately if Start was never called.
+func (s *Service) Wait() error {
+	s.mu.Lock()
+	quitCh := s.quitCh
+	s.mu.Unlock()
+	if quitCh == nil {
+		return nil
 	}
+	<-quitCh
 
-	return s.closeChan, err
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopErr
 }
 
-// Stop shuts down the service.
-func (s *Service) Stop(reason string, exitCode int) error {
-	ec := &exitCode
-	_, err := s.shutdown(nil, reason, ec)
-	return err
+// ShutdownReason returns the ServiceExit recorded by Stop, including the
+// reason and any triggering or module-stop error. It's only meaningful
+// after Wait returns (or the caller otherwise knows the service has
+// stopped); before that it's the zero value.
+func (s *Service) ShutdownReason() ServiceExit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shutdownReason == nil {
+		return ServiceExit{}
+	}
+	return *s.shutdownReason
 }
 
+// startModules starts every module, respecting the dependency DAG built in
+// NewService: a module only starts once all of its dependencies have
+// finished starting, but modules that don't depend on each other still
+// start concurrently. The resolved start order is recorded in the service's
+// metrics scope for debugging.
 func (s *Service) startModules() map[Module]error {
+	s.dagMu.RLock()
+	dependencies := s.dependencies
+	s.dagMu.RUnlock()
+
+	byName := make(map[string]Module, len(s.modules))
+	for _, mod := range s.modules {
+		byName[mod.Name()] = mod
+	}
 
 	results := map[Module]error{}
-	wg := sync.WaitGroup{}
+	var resultsMu sync.Mutex
 
-	// make sure we wait for all the start
-	// calls to return
+	failed := map[string]bool{}
+	var failedMu sync.Mutex
+
+	done := make(map[string]chan struct{}, len(s.modules))
+	for name := range byName {
+		done[name] = make(chan struct{})
+	}
+
+	var order []string
+	var orderMu sync.Mutex
+
+	var wg sync.WaitGroup
 	wg.Add(len(s.modules))
-	for _, mod := range s.modules {
-		go func(m Module) {
+	for name, deps := range dependencies {
+		go func(name string, deps map[string]bool) {
+			defer wg.Done()
+			defer close(done[name])
+
+			depFailed := false
+			for dep := range deps {
+				<-done[dep]
+				failedMu.Lock()
+				if failed[dep] {
+					depFailed = true
+				}
+				failedMu.Unlock()
+			}
+
+			m := byName[name]
+			if depFailed {
+				resultsMu.Lock()
+				results[m] = fmt.Errorf("module %q not started: dependency failed to start", name)
+				resultsMu.Unlock()
+				failedMu.Lock()
+				failed[name] = true
+				failedMu.Unlock()
+				return
+			}
+
 			if !m.IsRunning() {
-				startResult := m.Start()
-				if startError := <-startResult; startError != nil {
+				if startError := <-m.Start(); startError != nil {
+					resultsMu.Lock()
 					results[m] = startError
+					resultsMu.Unlock()
+					failedMu.Lock()
+					failed[name] = true
+					failedMu.Unlock()
+					return
 				}
 			}
-			wg.Done()
-		}(mod)
-	}
 
-	// wait for the modules to all start
+			orderMu.Lock()
+			order = append(order, name)
+			orderMu.Unlock()
+		}(name, deps)
+	}
 	wg.Wait()
+
+	for i, name := range order {
+		s.Scope().Counter(fmt.Sprintf("module.%s.start_order", name)).Inc(int64(i))
+	}
+	log.Printf("module start order: %v", order)
+
 	return results
 }
 
+// shutdownTimeout returns how long stopModules waits for a single module's
+// Stop to return before giving up on it, falling back to
+// defaultShutdownTimeout if the service wasn't configured with one.
+func (s *Service) shutdownTimeout() time.Duration {
+	if s.config.ShutdownTimeout > 0 {
+		return s.config.ShutdownTimeout
+	}
+	return defaultShutdownTimeout
+}
+
+// stopModules stops every module in the reverse of its start order: a
+// module isn't stopped until everything that depends on it has already
+// stopped, but modules with no shared dependents still stop concurrently.
+// Each module's Stop is bounded by shutdownTimeout, so one bad module can't
+// block the rest of the shutdown indefinitely.
 func (s *Service) stopModules() map[Module]error {
+	s.dagMu.RLock()
+	dependents := s.dependents
+	s.dagMu.RUnlock()
+
+	timeout := s.shutdownTimeout()
+
+	byName := make(map[string]Module, len(s.modules))
+	for _, mod := range s.modules {
+		byName[mod.Name()] = mod
+	}
+
 	results := map[Module]error{}
-	wg := sync.WaitGroup{}
+	var resultsMu sync.Mutex
+
+	done := make(map[string]chan struct{}, len(s.modules))
+	for name := range byName {
+		done[name] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
 	wg.Add(len(s.modules))
-	for _, mod := range s.modules {
-		go func(m Module) {
-			if !m.IsRunning() {
-				// TODO: have a timeout here so a bad shutdown
-				// doesn't block everyone
-				if err := m.Stop(); err != nil {
-					results[m] = err
+	for name, dependents := range dependents {
+		go func(name string, dependents map[string]bool) {
+			defer wg.Done()
+			defer close(done[name])
+
+			for dependent := range dependents {
+				<-done[dependent]
+			}
+
+			m := byName[name]
+			if m.IsRunning() {
+				stopped := make(chan error, 1)
+				go func() { stopped <- m.Stop() }()
+
+				select {
+				case err := <-stopped:
+					if err != nil {
+						resultsMu.Lock()
+						results[m] = err
+						resultsMu.Unlock()
+					}
+				case <-time.After(timeout):
+					resultsMu.Lock()
+					results[m] = fmt.Errorf("module %q: stop did not return within %s", name, timeout)
+					resultsMu.Unlock()
 				}
 			}
-			wg.Done()
-		}(mod)
+		}(name, dependents)
 	}
 	wg.Wait()
 	return results
 }
 
-type ServiceExitCallback func(shutdown ServiceExit) int
+// buildModuleDAG resolves each module's declared Dependencies() into two
+// adjacency maps keyed by module name: dependencies[a][b] means a depends
+// on b, and dependents[b][a] is the inverse edge used to walk the DAG in
+// reverse on Stop. It fails if a module declares a dependency on an unknown
+// module, or if the declared dependencies form a cycle.
+func buildModuleDAG(mods []Module) (dependencies, dependents map[string]map[string]bool, err error) {
+	names := make(map[string]bool, len(mods))
+	for _, m := range mods {
+		names[m.Name()] = true
+	}
 
-func (s *Service) WaitForShutdown(exitCallback ServiceExitCallback) {
+	dependencies = make(map[string]map[string]bool, len(mods))
+	dependents = make(map[string]map[string]bool, len(mods))
+	for _, m := range mods {
+		dependencies[m.Name()] = map[string]bool{}
+		dependents[m.Name()] = map[string]bool{}
+	}
 
-	shutdown := <-s.closeChan
-	log.Printf("\nShutting down because %q\n", shutdown.Reason)
+	for _, m := range mods {
+		for _, dep := range moduleDependencies(m) {
+			if !names[dep] {
+				return nil, nil, fmt.Errorf("module %q declares a dependency on unknown module %q", m.Name(), dep)
+			}
+			dependencies[m.Name()][dep] = true
+			dependents[dep][m.Name()] = true
+		}
+	}
+
+	if cycle := findModuleCycle(dependencies); cycle != nil {
+		return nil, nil, fmt.Errorf("module dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
 
-	exit := 0
-	if exitCallback != nil {
-		exit = exitCallback(shutdown)
-	} else if shutdown.Error != nil {
-		exit = 1
+	return dependencies, dependents, nil
+}
+
+// findModuleCycle runs a DFS over the dependency graph and returns the
+// names making up the first cycle it finds, in order, or nil if the graph
+// is acyclic.
+func findModuleCycle(dependencies map[string]map[string]bool) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(dependencies))
+	var stack []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		stack = append(stack, name)
+
+		deps := make([]string, 0, len(dependencies[name]))
+		for dep := range dependencies[name] {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
+
+		for _, dep := range deps {
+			switch state[dep] {
+			case visiting:
+				cycleStart := 0
+				for i, n := range stack {
+					if n == dep {
+						cycleStart = i
+						break
+					}
+				}
+				return append(append([]string{}, stack[cycleStart:]...), dep)
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		state[name] = visited
+		stack = stack[:len(stack)-1]
+		return nil
+	}
+
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// defaultShutdownTimeout is how long stopModules waits for each module's
+// Stop to return when the service's config doesn't set ShutdownTimeout.
+const defaultShutdownTimeout = 30 * time.Second
+
+// Run starts the service and blocks until the process receives
+// SIGINT/SIGTERM or OnCriticalError decides to shut down, then stops the
+// service and returns an exit code derived from the shutdown reason. It's the
+// process-level entry point services should use in main() instead of
+// hand-rolling signal handling around Start/Wait.
+func (s *Service) Run() int {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	quitCh, err := s.Start(false)
+	if err != nil {
+		log.Printf("service didn't start cleanly: %v", err)
+		return 1
 	}
-	os.Exit(exit)
-}
\ No newline at end of file
+
+	select {
+	case <-quitCh:
+	case <-ctx.Done():
+		s.Stop("signal received", 0)
+		<-quitCh
+	}
+
+	shutdown := s.ShutdownReason()
+	log.Printf("\nShutting down because %q\n", shutdown.Reason)
+	if shutdown.Error != nil {
+		return 1
+	}
+	return shutdown.ExitCode
+}
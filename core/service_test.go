@@ -0,0 +1,292 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeModule is a Module whose Start/Stop behavior is configurable, for
+// exercising Service's lifecycle without a real config/metrics setup.
+type fakeModule struct {
+	name      string
+	startErr  error
+	stopErr   error
+	blockStop <-chan struct{} // if set, Stop blocks until this is closed
+	deps      []string        // returned from Dependencies(), if non-nil
+	order     *orderRecorder  // if set, records Name() on every Start/Stop
+	mu        sync.Mutex
+	running   bool
+}
+
+func (m *fakeModule) Name() string    { return m.name }
+func (m *fakeModule) Roles() []string { return nil }
+
+// Dependencies implements ModuleDependencies.
+func (m *fakeModule) Dependencies() []string { return m.deps }
+
+func (m *fakeModule) IsRunning() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running
+}
+
+func (m *fakeModule) Start() <-chan error {
+	result := make(chan error, 1)
+	m.mu.Lock()
+	if m.startErr == nil {
+		m.running = true
+	}
+	m.mu.Unlock()
+	if m.startErr == nil && m.order != nil {
+		m.order.record(m.name)
+	}
+	result <- m.startErr
+	return result
+}
+
+func (m *fakeModule) Stop() error {
+	if m.blockStop != nil {
+		<-m.blockStop
+	}
+	m.mu.Lock()
+	m.running = false
+	m.mu.Unlock()
+	if m.order != nil {
+		m.order.record(m.name)
+	}
+	return m.stopErr
+}
+
+// orderRecorder records the order in which a set of fakeModules are started
+// or stopped, so tests can assert on the DAG scheduler's behavior.
+type orderRecorder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (o *orderRecorder) record(name string) {
+	o.mu.Lock()
+	o.names = append(o.names, name)
+	o.mu.Unlock()
+}
+
+// indexOf returns the position of name in the recorded order, or -1 if it
+// was never recorded.
+func (o *orderRecorder) indexOf(name string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i, n := range o.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// newTestService builds a Service around mods without going through
+// NewService, so tests can hand it modules directly instead of through a
+// config.ConfigurationProvider and ModuleCreateFuncs.
+func newTestService(t *testing.T, mods ...Module) *Service {
+	t.Helper()
+
+	dependencies, dependents, err := buildModuleDAG(mods)
+	if err != nil {
+		t.Fatalf("buildModuleDAG: %v", err)
+	}
+
+	return &Service{
+		name:         "test",
+		modules:      mods,
+		dependencies: dependencies,
+		dependents:   dependents,
+	}
+}
+
+func TestServiceLifecycle(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "stop before start is a no-op that still unblocks Wait",
+			run: func(t *testing.T) {
+				svc := newTestService(t, &fakeModule{name: "a"})
+
+				if err := svc.Stop("never started", 0); err != nil {
+					t.Fatalf("Stop: %v", err)
+				}
+				if err := svc.Wait(); err != nil {
+					t.Fatalf("Wait: %v", err)
+				}
+				if got := svc.ShutdownReason().Reason; got != "never started" {
+					t.Fatalf("ShutdownReason().Reason = %q, want %q", got, "never started")
+				}
+			},
+		},
+		{
+			name: "start after stop returns an error and the same quitCh",
+			run: func(t *testing.T) {
+				svc := newTestService(t, &fakeModule{name: "a"})
+
+				if err := svc.Stop("shutting down early", 0); err != nil {
+					t.Fatalf("Stop: %v", err)
+				}
+
+				quitCh, err := svc.Start(false)
+				if err == nil {
+					t.Fatal("Start after Stop: got nil error, want non-nil")
+				}
+				select {
+				case <-quitCh:
+				default:
+					t.Fatal("Start after Stop: quitCh is not already closed")
+				}
+			},
+		},
+		{
+			name: "partial start failure stops whatever did start and surfaces the error",
+			run: func(t *testing.T) {
+				good := &fakeModule{name: "good"}
+				bad := &fakeModule{name: "bad", startErr: errors.New("boom")}
+				svc := newTestService(t, good, bad)
+
+				quitCh, err := svc.Start(false)
+				if err == nil {
+					t.Fatal("Start: got nil error, want non-nil")
+				}
+				<-quitCh
+
+				if good.IsRunning() {
+					t.Fatal("good module still running after partial start failure")
+				}
+				if err := svc.Wait(); err == nil {
+					t.Fatal("Wait: got nil error, want the aggregated start error")
+				}
+			},
+		},
+		{
+			name: "a module that never returns from Stop doesn't block shutdown forever",
+			run: func(t *testing.T) {
+				block := make(chan struct{})
+				defer close(block)
+
+				hung := &fakeModule{name: "hung", blockStop: block}
+				svc := newTestService(t, hung)
+				svc.config.ShutdownTimeout = 20 * time.Millisecond
+
+				if _, err := svc.Start(false); err != nil {
+					t.Fatalf("Start: %v", err)
+				}
+
+				done := make(chan error, 1)
+				go func() { done <- svc.Stop("test", 0) }()
+
+				select {
+				case err := <-done:
+					if err == nil {
+						t.Fatal("Stop: got nil error, want a stop-timed-out error")
+					}
+				case <-time.After(time.Second):
+					t.Fatal("Stop did not return within 1s of its 20ms module timeout")
+				}
+			},
+		},
+		{
+			name: "concurrent Stop calls agree on one terminal error",
+			run: func(t *testing.T) {
+				mods := []Module{
+					&fakeModule{name: "a"},
+					&fakeModule{name: "b", stopErr: errors.New("stop failed")},
+				}
+				svc := newTestService(t, mods...)
+
+				if _, err := svc.Start(false); err != nil {
+					t.Fatalf("Start: %v", err)
+				}
+
+				const n = 10
+				errs := make([]error, n)
+				var wg sync.WaitGroup
+				wg.Add(n)
+				for i := 0; i < n; i++ {
+					go func(i int) {
+						defer wg.Done()
+						errs[i] = svc.Stop("test", 0)
+					}(i)
+				}
+				wg.Wait()
+
+				for i, err := range errs {
+					if err == nil {
+						t.Fatalf("Stop() call %d: got nil error, want the aggregated stop error", i)
+					}
+					if err.Error() != errs[0].Error() {
+						t.Fatalf("Stop() call %d returned %q, want %q", i, err, errs[0])
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func TestModuleDAGOrdering(t *testing.T) {
+	t.Run("a module starts after its dependencies and stops before them", func(t *testing.T) {
+		startOrder := &orderRecorder{}
+		stopOrder := &orderRecorder{}
+
+		base := &fakeModule{name: "base", order: startOrder}
+		mid := &fakeModule{name: "mid", deps: []string{"base"}, order: startOrder}
+		top := &fakeModule{name: "top", deps: []string{"mid"}, order: startOrder}
+		svc := newTestService(t, base, mid, top)
+
+		if _, err := svc.Start(false); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		if startOrder.indexOf("base") >= startOrder.indexOf("mid") {
+			t.Fatalf("base must start before mid; got order %v", startOrder.names)
+		}
+		if startOrder.indexOf("mid") >= startOrder.indexOf("top") {
+			t.Fatalf("mid must start before top; got order %v", startOrder.names)
+		}
+
+		base.order, mid.order, top.order = stopOrder, stopOrder, stopOrder
+		if err := svc.Stop("test", 0); err != nil {
+			t.Fatalf("Stop: %v", err)
+		}
+		if stopOrder.indexOf("top") >= stopOrder.indexOf("mid") {
+			t.Fatalf("top must stop before mid; got order %v", stopOrder.names)
+		}
+		if stopOrder.indexOf("mid") >= stopOrder.indexOf("base") {
+			t.Fatalf("mid must stop before base; got order %v", stopOrder.names)
+		}
+	})
+
+	t.Run("a dependency cycle is rejected with an error naming the cycle", func(t *testing.T) {
+		a := &fakeModule{name: "a", deps: []string{"b"}}
+		b := &fakeModule{name: "b", deps: []string{"a"}}
+
+		_, _, err := buildModuleDAG([]Module{a, b})
+		if err == nil {
+			t.Fatal("buildModuleDAG: got nil error, want a cycle error")
+		}
+		if !strings.Contains(err.Error(), "a") || !strings.Contains(err.Error(), "b") {
+			t.Fatalf("buildModuleDAG error %q doesn't name both modules in the cycle", err)
+		}
+	})
+
+	t.Run("a dependency on an unknown module is rejected", func(t *testing.T) {
+		a := &fakeModule{name: "a", deps: []string{"missing"}}
+
+		_, _, err := buildModuleDAG([]Module{a})
+		if err == nil {
+			t.Fatal("buildModuleDAG: got nil error, want an unknown-dependency error")
+		}
+	})
+}
@@ -22,11 +22,21 @@ package fxtest
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"go.uber.org/fx"
 	"go.uber.org/fx/internal/lifecycle"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
+// defaultRunShutdownTimeout bounds how long Run waits for Stop to finish
+// once a shutdown has been triggered.
+const defaultRunShutdownTimeout = 30 * time.Second
+
 // TB is a subset of the standard library's testing.TB interface. It's
 // satisfied by both *testing.T and *testing.B.
 type TB interface {
@@ -43,6 +53,15 @@ func (p *testPrinter) Printf(format string, args ...interface{}) {
 	p.Logf(format, args...)
 }
 
+// NewZapObserver returns a *zap.Logger backed by zaptest/observer, along
+// with the observed log handle, so tests can pass the logger to
+// fx.WithZapLogger and then assert on the provide/invoke/lifecycle entries
+// it emitted.
+func NewZapObserver(t TB) (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.DebugLevel)
+	return zap.New(core), logs
+}
+
 // App is a wrapper around fx.App that provides some testing helpers. By
 // default, it uses the provided TB as the application's logging backend.
 type App struct {
@@ -54,7 +73,7 @@ type App struct {
 // New creates a new test application.
 func New(tb TB, opts ...fx.Option) *App {
 	allOpts := make([]fx.Option, 0, len(opts)+1)
-	allOpts = append(allOpts, fx.Logger(&testPrinter{tb}))
+	allOpts = append(allOpts, fx.WithPrinter(&testPrinter{tb}))
 	allOpts = append(allOpts, opts...)
 	return &App{
 		App: fx.New(allOpts...),
@@ -79,6 +98,50 @@ func (app *App) MustStop() {
 	}
 }
 
+// WaitReady returns ctx.Err() if ctx is already done, and nil otherwise. It
+// never blocks, and it doesn't check whether Start has been called or has
+// succeeded: called before Start, it still returns nil. Unlike core.Service, an fx.App has no per-module readiness to
+// aggregate: its Lifecycle hooks all run synchronously inside Start, so an
+// App that has already started successfully is as ready as it will ever
+// be. WaitReady exists so tests can write the same "start, then wait
+// ready" shape regardless of whether they're driving an App or a
+// core.Service; called after MustStart, it returns immediately.
+func (app *App) WaitReady(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return nil
+	}
+}
+
+// Run starts the application, blocks until the test process receives
+// SIGINT/SIGTERM, and then stops the application within
+// defaultRunShutdownTimeout. It mirrors fx.Run for tests that want to drive
+// a real process lifecycle (e.g. integration tests exercised against a
+// signal sent by the test harness) instead of calling MustStart/MustStop
+// directly.
+func (app *App) Run() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := app.Start(ctx); err != nil {
+		app.tb.Errorf("application didn't start cleanly: %v", err)
+		app.tb.FailNow()
+		return
+	}
+
+	<-ctx.Done()
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), defaultRunShutdownTimeout)
+	defer stopCancel()
+
+	if err := app.Stop(stopCtx); err != nil {
+		app.tb.Errorf("application didn't stop cleanly: %v", err)
+		app.tb.FailNow()
+	}
+}
+
 var _ fx.Lifecycle = (*Lifecycle)(nil)
 
 // Lifecycle is a testing spy for fx.Lifecycle. It exposes Start and Stop
@@ -97,13 +160,21 @@ func NewLifecycle(t TB) *Lifecycle {
 	}
 }
 
-// Start executes all registered OnStart hooks in order, halting at the first
-// hook that doesn't succeed.
-func (l *Lifecycle) Start() error { return l.lc.Start() }
+// Start executes all registered OnStart hooks in order, halting at the
+// first hook that doesn't succeed. It's equivalent to StartCtx with a
+// background context.
+func (l *Lifecycle) Start() error { return l.StartCtx(context.Background()) }
+
+// StartCtx is Start, but lets the caller supply the context passed to each
+// hook so tests can exercise timeout and cancellation behavior.
+func (l *Lifecycle) StartCtx(ctx context.Context) error { return l.lc.Start(ctx) }
 
 // MustStart calls Start, failing the test if an error is encountered.
-func (l *Lifecycle) MustStart() *Lifecycle {
-	if err := l.Start(); err != nil {
+func (l *Lifecycle) MustStart() *Lifecycle { return l.MustStartCtx(context.Background()) }
+
+// MustStartCtx calls StartCtx, failing the test if an error is encountered.
+func (l *Lifecycle) MustStartCtx(ctx context.Context) *Lifecycle {
+	if err := l.StartCtx(ctx); err != nil {
 		l.t.Errorf("lifecycle didn't start cleanly: %v", err)
 		l.t.FailNow()
 	}
@@ -111,16 +182,23 @@ func (l *Lifecycle) MustStart() *Lifecycle {
 }
 
 // Stop calls all OnStop hooks whose OnStart counterpart was called, running
-// in reverse order.
+// in reverse order. It's equivalent to StopCtx with a background context.
 //
 // If any hook returns an error, execution continues for a best-effort
 // cleanup. Any errors encountered are collected into a single error and
 // returned.
-func (l *Lifecycle) Stop() error { return l.lc.Stop() }
+func (l *Lifecycle) Stop() error { return l.StopCtx(context.Background()) }
+
+// StopCtx is Stop, but lets the caller supply the context passed to each
+// hook so tests can exercise timeout and cancellation behavior.
+func (l *Lifecycle) StopCtx(ctx context.Context) error { return l.lc.Stop(ctx) }
 
 // MustStop calls Stop, failing the test if an error is encountered.
-func (l *Lifecycle) MustStop() {
-	if err := l.Stop(); err != nil {
+func (l *Lifecycle) MustStop() { l.MustStopCtx(context.Background()) }
+
+// MustStopCtx calls StopCtx, failing the test if an error is encountered.
+func (l *Lifecycle) MustStopCtx(ctx context.Context) {
+	if err := l.StopCtx(ctx); err != nil {
 		l.t.Errorf("lifecycle didn't stop cleanly: %v", err)
 		l.t.FailNow()
 	}
@@ -131,5 +209,17 @@ func (l *Lifecycle) Append(h fx.Hook) {
 	l.lc.Append(lifecycle.Hook{
 		OnStart: h.OnStart,
 		OnStop:  h.OnStop,
+		Caller:  lifecycle.CallerName(1),
 	})
 }
+
+// Hooks returns the hooks registered so far, in registration order, so
+// tests can assert on what was appended without re-running Start/Stop.
+func (l *Lifecycle) Hooks() []fx.Hook {
+	internalHooks := l.lc.Hooks()
+	hooks := make([]fx.Hook, len(internalHooks))
+	for i, h := range internalHooks {
+		hooks[i] = fx.Hook{OnStart: h.OnStart, OnStop: h.OnStop}
+	}
+	return hooks
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fxtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+func TestAppMustStartStop(t *testing.T) {
+	var order []string
+	app := New(t, fx.Invoke(func(lc fx.Lifecycle) {
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error { order = append(order, "start"); return nil },
+			OnStop:  func(context.Context) error { order = append(order, "stop"); return nil },
+		})
+	}))
+
+	app.MustStart()
+	app.MustStop()
+
+	if len(order) != 2 || order[0] != "start" || order[1] != "stop" {
+		t.Fatalf("order = %v, want [start stop]", order)
+	}
+}
+
+func TestAppWaitReady(t *testing.T) {
+	app := New(t).MustStart()
+	defer app.MustStop()
+
+	if err := app.WaitReady(context.Background()); err != nil {
+		t.Fatalf("WaitReady: %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := app.WaitReady(canceled); err != canceled.Err() {
+		t.Fatalf("WaitReady with a canceled context: got %v, want %v", err, canceled.Err())
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "Start and Stop run hooks in order",
+			run: func(t *testing.T) {
+				var order []string
+				lc := NewLifecycle(t)
+				lc.Append(fx.Hook{
+					OnStart: func(context.Context) error { order = append(order, "start"); return nil },
+					OnStop:  func(context.Context) error { order = append(order, "stop"); return nil },
+				})
+
+				lc.MustStart()
+				lc.MustStop()
+
+				if len(order) != 2 || order[0] != "start" || order[1] != "stop" {
+					t.Fatalf("order = %v, want [start stop]", order)
+				}
+			},
+		},
+		{
+			name: "StartCtx propagates a caller-provided timeout to a slow hook",
+			run: func(t *testing.T) {
+				lc := NewLifecycle(t)
+				lc.Append(fx.Hook{OnStart: func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}})
+
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+				if err := lc.StartCtx(ctx); err != context.DeadlineExceeded {
+					t.Fatalf("StartCtx: got %v, want context.DeadlineExceeded", err)
+				}
+			},
+		},
+		{
+			name: "Hooks returns what was registered without running anything",
+			run: func(t *testing.T) {
+				lc := NewLifecycle(t)
+				lc.Append(fx.Hook{OnStart: func(context.Context) error {
+					t.Fatal("Hooks should not run any hook")
+					return nil
+				}})
+
+				if got := len(lc.Hooks()); got != 1 {
+					t.Fatalf("len(Hooks()) = %d, want 1", got)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func TestNewZapObserver(t *testing.T) {
+	zlog, logs := NewZapObserver(t)
+
+	app := New(t, fx.WithZapLogger(zlog), fx.Invoke(func() error {
+		return errors.New("invoke failed")
+	}))
+
+	if app.Err() == nil {
+		t.Fatal("Err: got nil, want the invoke error")
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].Message != "invoke failed" {
+		t.Fatalf("logs = %+v, want a single \"invoke failed\" entry", entries)
+	}
+}
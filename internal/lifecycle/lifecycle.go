@@ -0,0 +1,182 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package lifecycle provides the low-level hook bookkeeping used to
+// implement fx.Lifecycle. It is internal so that the public API can evolve
+// independently of the storage details.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// Hook is a pair of start/stop callbacks. Either callback receives the
+// context passed to Lifecycle.Start/Stop, optionally bounded by a
+// per-hook timeout set with SetStartTimeout/SetStopTimeout.
+type Hook struct {
+	OnStart func(context.Context) error
+	OnStop  func(context.Context) error
+
+	// Caller identifies where this Hook was registered (typically the
+	// constructor that called Lifecycle.Append), for the "caller" field
+	// logged alongside each hook invocation. Callers that don't need this
+	// (e.g. internal adapters) may leave it empty.
+	Caller string
+}
+
+// Field is a single structured logging key/value pair, mirroring fx.Field.
+// It's redeclared here (rather than imported) because this package is
+// imported by fx itself and can't import it back.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger receives a structured log entry for each hook that's run, mirroring
+// fx.Logger. A nil Logger disables logging.
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+// CallerName returns the caller frame skip levels above its own caller, in
+// "file:line" form, for use as a Hook's Caller field. skip follows
+// runtime.Caller's convention: 0 would identify CallerName's own caller.
+func CallerName(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// Lifecycle coordinates a set of start/stop hooks, running starts in
+// registration order and stops in reverse order.
+type Lifecycle struct {
+	logger       Logger
+	hooks        []Hook
+	numStarted   int
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+}
+
+// New constructs a new Lifecycle. A nil logger disables logging.
+func New(logger Logger) *Lifecycle {
+	return &Lifecycle{logger: logger}
+}
+
+// SetStartTimeout bounds how long each individual OnStart hook is allowed
+// to run. A zero duration (the default) means no per-hook timeout.
+func (l *Lifecycle) SetStartTimeout(timeout time.Duration) {
+	l.startTimeout = timeout
+}
+
+// SetStopTimeout bounds how long each individual OnStop hook is allowed to
+// run. A zero duration (the default) means no per-hook timeout.
+func (l *Lifecycle) SetStopTimeout(timeout time.Duration) {
+	l.stopTimeout = timeout
+}
+
+// Append adds a Hook to the Lifecycle.
+func (l *Lifecycle) Append(hook Hook) {
+	l.hooks = append(l.hooks, hook)
+}
+
+// Hooks returns a copy of the hooks registered so far, in registration
+// order.
+func (l *Lifecycle) Hooks() []Hook {
+	hooks := make([]Hook, len(l.hooks))
+	copy(hooks, l.hooks)
+	return hooks
+}
+
+// Start runs all OnStart hooks in order, halting at and returning the first
+// error encountered. Each hook receives ctx, bounded by startTimeout if one
+// is set.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	for _, hook := range l.hooks {
+		if hook.OnStart != nil {
+			hookCtx, cancel := l.withTimeout(ctx, l.startTimeout)
+			start := time.Now()
+			err := hook.OnStart(hookCtx)
+			cancel()
+			l.logHook("OnStart", hook.Caller, time.Since(start), err)
+			if err != nil {
+				return err
+			}
+		}
+		l.numStarted++
+	}
+	return nil
+}
+
+// Stop runs any OnStop hooks whose OnStart has already run, in reverse
+// order. It keeps going even if a hook fails, aggregating all errors
+// encountered. Each hook receives ctx, bounded by stopTimeout if one is
+// set.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	var errs []error
+	for ; l.numStarted > 0; l.numStarted-- {
+		hook := l.hooks[l.numStarted-1]
+		if hook.OnStop == nil {
+			continue
+		}
+		hookCtx, cancel := l.withTimeout(ctx, l.stopTimeout)
+		start := time.Now()
+		err := hook.OnStop(hookCtx)
+		cancel()
+		l.logHook("OnStop", hook.Caller, time.Since(start), err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return fmt.Errorf("%v (and %d other errors)", errs[0], len(errs)-1)
+}
+
+// logHook reports a single hook invocation, if a Logger is installed.
+func (l *Lifecycle) logHook(kind, caller string, duration time.Duration, err error) {
+	if l.logger == nil {
+		return
+	}
+	fields := []Field{{Key: "caller", Value: caller}, {Key: "duration", Value: duration}}
+	if err != nil {
+		l.logger.Error(kind+" failed", append(fields, Field{Key: "error", Value: err})...)
+		return
+	}
+	l.logger.Debug(kind, fields...)
+}
+
+func (l *Lifecycle) withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,201 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeLogger records every structured log entry it receives, for assertions
+// on what Lifecycle reports per hook.
+type fakeLogger struct {
+	entries []string
+}
+
+func (f *fakeLogger) Debug(msg string, fields ...Field) { f.entries = append(f.entries, "DEBUG:"+msg) }
+func (f *fakeLogger) Info(msg string, fields ...Field)  { f.entries = append(f.entries, "INFO:"+msg) }
+func (f *fakeLogger) Warn(msg string, fields ...Field)  { f.entries = append(f.entries, "WARN:"+msg) }
+func (f *fakeLogger) Error(msg string, fields ...Field) { f.entries = append(f.entries, "ERROR:"+msg) }
+
+func TestLifecycle(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "Start runs hooks in order and Stop runs them in reverse",
+			run: func(t *testing.T) {
+				var order []string
+				lc := New(nil)
+				lc.Append(Hook{
+					OnStart: func(context.Context) error { order = append(order, "start-a"); return nil },
+					OnStop:  func(context.Context) error { order = append(order, "stop-a"); return nil },
+				})
+				lc.Append(Hook{
+					OnStart: func(context.Context) error { order = append(order, "start-b"); return nil },
+					OnStop:  func(context.Context) error { order = append(order, "stop-b"); return nil },
+				})
+
+				if err := lc.Start(context.Background()); err != nil {
+					t.Fatalf("Start: %v", err)
+				}
+				if err := lc.Stop(context.Background()); err != nil {
+					t.Fatalf("Stop: %v", err)
+				}
+
+				want := []string{"start-a", "start-b", "stop-b", "stop-a"}
+				if len(order) != len(want) {
+					t.Fatalf("order = %v, want %v", order, want)
+				}
+				for i := range want {
+					if order[i] != want[i] {
+						t.Fatalf("order = %v, want %v", order, want)
+					}
+				}
+			},
+		},
+		{
+			name: "Start halts at the first failing hook and only its predecessors are stopped",
+			run: func(t *testing.T) {
+				var stopped []string
+				lc := New(nil)
+				lc.Append(Hook{
+					OnStart: func(context.Context) error { return nil },
+					OnStop:  func(context.Context) error { stopped = append(stopped, "a"); return nil },
+				})
+				lc.Append(Hook{
+					OnStart: func(context.Context) error { return errors.New("boom") },
+					OnStop:  func(context.Context) error { stopped = append(stopped, "b"); return nil },
+				})
+				lc.Append(Hook{
+					OnStart: func(context.Context) error { t.Fatal("third hook should not start"); return nil },
+				})
+
+				if err := lc.Start(context.Background()); err == nil || err.Error() != "boom" {
+					t.Fatalf("Start: got %v, want boom", err)
+				}
+				if err := lc.Stop(context.Background()); err != nil {
+					t.Fatalf("Stop: %v", err)
+				}
+				if len(stopped) != 1 || stopped[0] != "a" {
+					t.Fatalf("stopped = %v, want [a]", stopped)
+				}
+			},
+		},
+		{
+			name: "Stop keeps going after a failing hook and aggregates every error",
+			run: func(t *testing.T) {
+				lc := New(nil)
+				lc.Append(Hook{
+					OnStart: func(context.Context) error { return nil },
+					OnStop:  func(context.Context) error { return errors.New("first") },
+				})
+				lc.Append(Hook{
+					OnStart: func(context.Context) error { return nil },
+					OnStop:  func(context.Context) error { return errors.New("second") },
+				})
+
+				if err := lc.Start(context.Background()); err != nil {
+					t.Fatalf("Start: %v", err)
+				}
+				err := lc.Stop(context.Background())
+				if err == nil {
+					t.Fatal("Stop: got nil error, want an aggregated error")
+				}
+				if got := err.Error(); got != "second (and 1 other errors)" {
+					t.Fatalf("Stop error = %q, want the first-run OnStop hook's error plus a count", got)
+				}
+			},
+		},
+		{
+			name: "SetStartTimeout cancels the context passed to a slow OnStart hook",
+			run: func(t *testing.T) {
+				lc := New(nil)
+				lc.SetStartTimeout(10 * time.Millisecond)
+				lc.Append(Hook{
+					OnStart: func(ctx context.Context) error {
+						<-ctx.Done()
+						return ctx.Err()
+					},
+				})
+
+				err := lc.Start(context.Background())
+				if err != context.DeadlineExceeded {
+					t.Fatalf("Start: got %v, want context.DeadlineExceeded", err)
+				}
+			},
+		},
+		{
+			name: "SetStopTimeout cancels the context passed to a slow OnStop hook",
+			run: func(t *testing.T) {
+				lc := New(nil)
+				lc.SetStopTimeout(10 * time.Millisecond)
+				lc.Append(Hook{
+					OnStart: func(context.Context) error { return nil },
+					OnStop: func(ctx context.Context) error {
+						<-ctx.Done()
+						return ctx.Err()
+					},
+				})
+
+				if err := lc.Start(context.Background()); err != nil {
+					t.Fatalf("Start: %v", err)
+				}
+				if err := lc.Stop(context.Background()); err != context.DeadlineExceeded {
+					t.Fatalf("Stop: got %v, want context.DeadlineExceeded", err)
+				}
+			},
+		},
+		{
+			name: "Hooks returns a copy of the registered hooks in order",
+			run: func(t *testing.T) {
+				lc := New(nil)
+				lc.Append(Hook{Caller: "a"})
+				lc.Append(Hook{Caller: "b"})
+
+				hooks := lc.Hooks()
+				if len(hooks) != 2 || hooks[0].Caller != "a" || hooks[1].Caller != "b" {
+					t.Fatalf("Hooks() = %+v, want [{Caller:a} {Caller:b}]", hooks)
+				}
+
+				hooks[0].Caller = "mutated"
+				if lc.Hooks()[0].Caller != "a" {
+					t.Fatal("Hooks() did not return an independent copy")
+				}
+			},
+		},
+		{
+			name: "a Logger sees one entry per hook, including failures",
+			run: func(t *testing.T) {
+				logger := &fakeLogger{}
+				lc := New(logger)
+				lc.Append(Hook{
+					OnStart: func(context.Context) error { return nil },
+					OnStop:  func(context.Context) error { return errors.New("stop failed") },
+				})
+
+				if err := lc.Start(context.Background()); err != nil {
+					t.Fatalf("Start: %v", err)
+				}
+				if err := lc.Stop(context.Background()); err == nil {
+					t.Fatal("Stop: got nil error, want stop failed")
+				}
+
+				want := []string{"DEBUG:OnStart", "ERROR:OnStop failed"}
+				if len(logger.entries) != len(want) {
+					t.Fatalf("logger.entries = %v, want %v", logger.entries, want)
+				}
+				for i := range want {
+					if logger.entries[i] != want[i] {
+						t.Fatalf("logger.entries = %v, want %v", logger.entries, want)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
@@ -0,0 +1,53 @@
+// Package config is a minimal stand-in for uber-go/uberfx's real
+// configuration package, just enough to let go.uber.org/fx/core build and
+// test in isolation. It is not meant to be a usable configuration system.
+package config
+
+// Well-known keys read by core.Service from a ConfigurationProvider.
+const (
+	ApplicationIDKey          = "applicationid"
+	ApplicationOwnerKey       = "applicationowner"
+	ApplicationDescriptionKey = "applicationdesc"
+)
+
+// ConfigurationProvider resolves configuration values by key.
+type ConfigurationProvider interface {
+	// GetValue returns the value at name, or def if it's unset.
+	GetValue(name string, def interface{}) Value
+	// MustGetValue returns the value at name, panicking if it's unset.
+	MustGetValue(name string) Value
+}
+
+// Value is a single configuration value.
+type Value struct {
+	value interface{}
+}
+
+// AsString returns the value as a string, or "" if it isn't one.
+func (v Value) AsString() string {
+	s, _ := v.value.(string)
+	return s
+}
+
+// PopulateStruct reports whether it populated target. This stub never
+// populates anything and always returns false.
+func (v Value) PopulateStruct(target interface{}) bool {
+	return false
+}
+
+// emptyProvider holds no values: GetValue always returns def, and
+// MustGetValue returns an empty Value instead of panicking.
+type emptyProvider struct{}
+
+func (emptyProvider) GetValue(name string, def interface{}) Value {
+	return Value{value: def}
+}
+
+func (emptyProvider) MustGetValue(name string) Value {
+	return Value{}
+}
+
+// Global returns the process-wide ConfigurationProvider.
+func Global() ConfigurationProvider {
+	return emptyProvider{}
+}
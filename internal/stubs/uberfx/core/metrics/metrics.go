@@ -0,0 +1,38 @@
+// Package metrics is a minimal stand-in for uber-go/uberfx's real metrics
+// package, just enough to let go.uber.org/fx/core build and test in
+// isolation. It is not meant to be a usable metrics system.
+package metrics
+
+// Scope records metrics under a namespace.
+type Scope interface {
+	Counter(name string) Counter
+	Gauge(name string) Gauge
+}
+
+// Counter is a monotonically increasing value.
+type Counter interface {
+	Inc(delta int64)
+}
+
+// Gauge is a point-in-time value.
+type Gauge interface {
+	Update(value float64)
+}
+
+type noopScope struct{}
+
+func (noopScope) Counter(name string) Counter { return noopCounter{} }
+func (noopScope) Gauge(name string) Gauge     { return noopGauge{} }
+
+type noopCounter struct{}
+
+func (noopCounter) Inc(delta int64) {}
+
+type noopGauge struct{}
+
+func (noopGauge) Update(value float64) {}
+
+// Global returns a process-wide Scope. reporting is ignored by this stub.
+func Global(reporting bool) Scope {
+	return noopScope{}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"context"
+
+	"go.uber.org/fx/internal/lifecycle"
+)
+
+// Hook is a pair of start/stop callbacks, either of which can be nil. Both
+// receive the context passed to App.Start/Stop, so they can honor a
+// shutdown deadline or propagate cancellation to whatever they manage
+// (net.Listeners, DB drivers, and so on). StartTimeout/StopTimeout further
+// bound how long an individual hook is allowed to run.
+type Hook struct {
+	OnStart func(context.Context) error
+	OnStop  func(context.Context) error
+}
+
+// HookFromFuncs adapts legacy zero-argument start/stop callbacks into a
+// context-aware Hook. It exists for callers upgrading from the old Hook
+// signature; new code should just populate Hook directly. Either argument
+// may be nil.
+func HookFromFuncs(onStart, onStop func() error) Hook {
+	return Hook{OnStart: adaptHookFunc(onStart), OnStop: adaptHookFunc(onStop)}
+}
+
+func adaptHookFunc(fn func() error) func(context.Context) error {
+	if fn == nil {
+		return nil
+	}
+	return func(context.Context) error { return fn() }
+}
+
+// Lifecycle allows constructors to register callbacks that are executed on
+// application start and stop.
+type Lifecycle interface {
+	Append(Hook)
+}
+
+// lifecycleWrapper adapts the internal lifecycle used by the dependency
+// injection container to the public Lifecycle interface.
+type lifecycleWrapper struct {
+	lc *lifecycle.Lifecycle
+}
+
+func (w *lifecycleWrapper) Append(h Hook) {
+	w.lc.Append(lifecycle.Hook{
+		OnStart: h.OnStart,
+		OnStop:  h.OnStop,
+		Caller:  lifecycle.CallerName(1),
+	})
+}
+
+func (w *lifecycleWrapper) Start(ctx context.Context) error { return w.lc.Start(ctx) }
+func (w *lifecycleWrapper) Stop(ctx context.Context) error  { return w.lc.Stop(ctx) }
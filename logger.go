@@ -0,0 +1,113 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"fmt"
+
+	"go.uber.org/fx/internal/lifecycle"
+)
+
+// Field is a single structured logging key/value pair. It's modeled on
+// zap.Field so that fx events translate directly onto a *zap.Logger; see
+// WithZapLogger.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F creates a Field.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Logger is the structured, leveled logging interface fx uses to report
+// provide/invoke/lifecycle events. Install one with WithLogger, or use
+// WithZapLogger to back it with a *zap.Logger. Apps that only set a Printer
+// via WithPrinter get one of these for free, adapted at INFO level.
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+// WithLogger sets the Logger used to report App events (provide, invoke,
+// and lifecycle start/stop). It takes precedence over any Printer set with
+// WithPrinter.
+func WithLogger(l Logger) Option {
+	return optionFunc(func(app *App) {
+		app.logger = l
+	})
+}
+
+// printerLogger adapts a Printer into a Logger. Printer has no notion of
+// level, so every call is funneled through Printf as if it were logged at
+// INFO: it's a shim for callers who haven't moved to the structured Logger
+// interface, not a substitute for real leveled logging.
+type printerLogger struct {
+	p Printer
+}
+
+func (l printerLogger) Debug(msg string, fields ...Field) { l.log("INFO", msg, fields) }
+func (l printerLogger) Info(msg string, fields ...Field)  { l.log("INFO", msg, fields) }
+func (l printerLogger) Warn(msg string, fields ...Field)  { l.log("INFO", msg, fields) }
+func (l printerLogger) Error(msg string, fields ...Field) { l.log("INFO", msg, fields) }
+
+func (l printerLogger) log(level, msg string, fields []Field) {
+	l.p.Printf("%s\t%s%s", level, msg, formatFields(fields))
+}
+
+// lifecycleLogger adapts a Logger into the internal lifecycle package's
+// Logger interface, so per-hook start/stop events (name, caller, duration,
+// error) flow through the same structured path - and the same
+// WithZapLogger backend - as provide/invoke events.
+type lifecycleLogger struct {
+	l Logger
+}
+
+func (a lifecycleLogger) Debug(msg string, fields ...lifecycle.Field) { a.log(a.l.Debug, msg, fields) }
+func (a lifecycleLogger) Info(msg string, fields ...lifecycle.Field)  { a.log(a.l.Info, msg, fields) }
+func (a lifecycleLogger) Warn(msg string, fields ...lifecycle.Field)  { a.log(a.l.Warn, msg, fields) }
+func (a lifecycleLogger) Error(msg string, fields ...lifecycle.Field) { a.log(a.l.Error, msg, fields) }
+
+func (a lifecycleLogger) log(level func(string, ...Field), msg string, fields []lifecycle.Field) {
+	level(msg, toFxFields(fields)...)
+}
+
+func toFxFields(fields []lifecycle.Field) []Field {
+	fxFields := make([]Field, len(fields))
+	for i, f := range fields {
+		fxFields[i] = Field{Key: f.Key, Value: f.Value}
+	}
+	return fxFields
+}
+
+func formatFields(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	s := ""
+	for _, f := range fields {
+		s += fmt.Sprintf(" %s=%v", f.Key, f.Value)
+	}
+	return s
+}
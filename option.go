@@ -0,0 +1,80 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import "time"
+
+// Option configures an App using the functional options pattern.
+type Option interface {
+	apply(*App)
+}
+
+type optionFunc func(*App)
+
+func (f optionFunc) apply(app *App) { f(app) }
+
+// WithPrinter sets the Printer used to log App events. It defaults to a
+// logger backed by the standard library's log package. Prefer WithLogger
+// or WithZapLogger for structured, leveled logging; a Printer set here is
+// only used if no Logger has been installed.
+func WithPrinter(p Printer) Option {
+	return optionFunc(func(app *App) {
+		app.log = p
+	})
+}
+
+// Provide registers constructors with the dependency injection container.
+// Constructors are deferred and only invoked while building the Invoke
+// graph.
+func Provide(constructors ...interface{}) Option {
+	return optionFunc(func(app *App) {
+		app.constructors = append(app.constructors, constructors...)
+	})
+}
+
+// Invoke registers functions to be run as part of building the
+// application's object graph. Invoked functions are eligible to receive
+// anything previously registered with Provide, including, for an App built
+// by Run, the App's shutdown context.
+func Invoke(funcs ...interface{}) Option {
+	return optionFunc(func(app *App) {
+		app.invokes = append(app.invokes, funcs...)
+	})
+}
+
+// StartTimeout bounds how long each individual OnStart hook is allowed to
+// run. The context passed to the hook is canceled once timeout elapses, so
+// a well-behaved hook should return promptly with ctx.Err().
+func StartTimeout(timeout time.Duration) Option {
+	return optionFunc(func(app *App) {
+		app.startTimeout = timeout
+	})
+}
+
+// StopTimeout bounds how long each individual OnStop hook is allowed to
+// run. If a hook doesn't return within timeout, the context passed to it is
+// canceled; Stop keeps running the remaining hooks and aggregates any
+// resulting errors.
+func StopTimeout(timeout time.Duration) Option {
+	return optionFunc(func(app *App) {
+		app.stopTimeout = timeout
+	})
+}
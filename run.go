@@ -0,0 +1,101 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout bounds how long Run will wait for Stop to finish
+// once a shutdown has been triggered.
+const defaultShutdownTimeout = 30 * time.Second
+
+// ShutdownTimeout overrides how long Run waits for the App to stop once a
+// shutdown has been triggered, either by a signal or a critical error.
+func ShutdownTimeout(timeout time.Duration) Option {
+	return optionFunc(func(app *App) {
+		app.shutdownTimeout = timeout
+	})
+}
+
+// Shutdowner reports critical errors encountered after the App has
+// started, triggering the same shutdown path as an OS signal. Run provides
+// one, so any Invoke function of an App built by Run can take it as an
+// argument; constructors registered with Provide take no arguments and so
+// can't receive it.
+type Shutdowner interface {
+	Shutdown(err error)
+}
+
+type shutdowner struct {
+	errCh chan error
+}
+
+func (s *shutdowner) Shutdown(err error) {
+	select {
+	case s.errCh <- err:
+	default:
+	}
+}
+
+// Run builds an App from the given Options, starts it, and blocks until
+// either the process receives SIGINT/SIGTERM or a Shutdowner reports a
+// critical error, then stops the App within ShutdownTimeout. It's the
+// entry point most long-running services should use instead of wiring
+// New/Start/Stop and signal handling by hand.
+func Run(opts ...Option) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	shutdown := &shutdowner{errCh: make(chan error, 1)}
+
+	allOpts := make([]Option, 0, len(opts)+2)
+	allOpts = append(allOpts, Provide(func() context.Context { return ctx }))
+	allOpts = append(allOpts, Provide(func() Shutdowner { return shutdown }))
+	allOpts = append(allOpts, opts...)
+
+	app := New(allOpts...)
+	if app.shutdownTimeout == 0 {
+		app.shutdownTimeout = defaultShutdownTimeout
+	}
+
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+	case runErr = <-shutdown.errCh:
+	}
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+	defer stopCancel()
+
+	if err := app.Stop(stopCtx); err != nil && runErr == nil {
+		runErr = err
+	}
+	return runErr
+}
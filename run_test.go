@@ -0,0 +1,95 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "a Shutdowner triggers the same shutdown path as a signal",
+			run: func(t *testing.T) {
+				want := errors.New("critical error")
+				err := Run(Invoke(func(s Shutdowner) {
+					go s.Shutdown(want)
+				}))
+				if err != want {
+					t.Fatalf("Run: got %v, want %v", err, want)
+				}
+			},
+		},
+		{
+			name: "ShutdownTimeout bounds how long Stop waits for a slow OnStop hook",
+			run: func(t *testing.T) {
+				done := make(chan struct{})
+				err := Run(
+					ShutdownTimeout(10*time.Millisecond),
+					Invoke(func(s Shutdowner, lc Lifecycle) {
+						lc.Append(Hook{OnStop: func(ctx context.Context) error {
+							defer close(done)
+							<-ctx.Done()
+							return ctx.Err()
+						}})
+						go s.Shutdown(nil)
+					}),
+				)
+				if err != context.DeadlineExceeded {
+					t.Fatalf("Run: got %v, want context.DeadlineExceeded", err)
+				}
+				select {
+				case <-done:
+				case <-time.After(time.Second):
+					t.Fatal("OnStop hook never observed its context being canceled")
+				}
+			},
+		},
+		{
+			name: "Run returns the build error without starting the App",
+			run: func(t *testing.T) {
+				ran := false
+				err := Run(
+					Provide(func(int) {}),
+					Invoke(func(lc Lifecycle) {
+						lc.Append(Hook{OnStart: func(context.Context) error { ran = true; return nil }})
+					}),
+				)
+				if err == nil {
+					t.Fatal("Run: got nil error, want the build error")
+				}
+				if ran {
+					t.Fatal("Run started a hook despite a build error")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import "go.uber.org/zap"
+
+// WithZapLogger installs z as the App's structured Logger, so that
+// provide/invoke/lifecycle events (constructor and invoke identity, hook
+// duration, errors) are reported as zap fields instead of plain text.
+func WithZapLogger(z *zap.Logger) Option {
+	return optionFunc(func(app *App) {
+		app.logger = zapLogger{z: z}
+	})
+}
+
+// zapLogger adapts a *zap.Logger to the fx.Logger interface.
+type zapLogger struct {
+	z *zap.Logger
+}
+
+func (l zapLogger) Debug(msg string, fields ...Field) { l.z.Debug(msg, toZapFields(fields)...) }
+func (l zapLogger) Info(msg string, fields ...Field)  { l.z.Info(msg, toZapFields(fields)...) }
+func (l zapLogger) Warn(msg string, fields ...Field)  { l.z.Warn(msg, toZapFields(fields)...) }
+func (l zapLogger) Error(msg string, fields ...Field) { l.z.Error(msg, toZapFields(fields)...) }
+
+func toZapFields(fields []Field) []zap.Field {
+	zfields := make([]zap.Field, len(fields))
+	for i, f := range fields {
+		zfields[i] = zap.Any(f.Key, f.Value)
+	}
+	return zfields
+}
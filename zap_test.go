@@ -0,0 +1,62 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithZapLogger(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	zlog := zap.New(core)
+
+	app := New(
+		WithZapLogger(zlog),
+		Provide(func() string { return "hello" }),
+		Invoke(func(string) error { return nil }),
+		Invoke(func(lc Lifecycle) {
+			lc.Append(Hook{OnStart: func(context.Context) error { return nil }})
+		}),
+	)
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	entries := logs.All()
+	wantMessages := []string{"provided", "invoked", "invoked", "OnStart", "started"}
+	if len(entries) != len(wantMessages) {
+		t.Fatalf("got %d log entries, want %d: %+v", len(entries), len(wantMessages), entries)
+	}
+	for i, msg := range wantMessages {
+		if entries[i].Message != msg {
+			t.Fatalf("entries[%d].Message = %q, want %q", i, entries[i].Message, msg)
+		}
+	}
+
+	provided := entries[0]
+	if got := provided.ContextMap()["type"]; got != "string" {
+		t.Fatalf("provided entry type field = %v, want string", got)
+	}
+}